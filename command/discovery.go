@@ -0,0 +1,195 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	consulapi "github.com/hashicorp/consul/api"
+	etcdclient "github.com/coreos/etcd/client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+	k8srest "k8s.io/client-go/rest"
+	k8sclientcmd "k8s.io/client-go/tools/clientcmd"
+)
+
+// VaultEndpoint is a single Vault node address discovered by a
+// ServiceDiscovery backend.
+type VaultEndpoint struct {
+	Address string
+}
+
+// ServiceDiscovery locates the Vault nodes registered under a service name
+// on whatever substrate the cluster runs on. InitCommand's "-auto" path
+// uses this to stay agnostic of the underlying discovery mechanism.
+type ServiceDiscovery interface {
+	// Discover returns every Vault node currently registered under service.
+	Discover(service string) ([]VaultEndpoint, error)
+}
+
+// newServiceDiscovery selects a ServiceDiscovery backend by the value of
+// "-auto-provider". Consul is the default, matching the original "-auto"
+// behavior before other providers existed.
+func newServiceDiscovery(provider string) (ServiceDiscovery, error) {
+	switch strings.ToLower(provider) {
+	case "", "consul":
+		return &consulServiceDiscovery{}, nil
+	case "dns":
+		return &dnsServiceDiscovery{}, nil
+	case "k8s":
+		return &k8sServiceDiscovery{}, nil
+	case "etcd":
+		return &etcdServiceDiscovery{}, nil
+	default:
+		return nil, fmt.Errorf("unknown -auto-provider %q (valid: consul, dns, k8s, etcd)", provider)
+	}
+}
+
+// consulServiceDiscovery discovers Vault nodes via the Consul catalog, using
+// the standard CONSUL_HTTP_ADDR / CONSUL_HTTP_TOKEN environment variables.
+type consulServiceDiscovery struct{}
+
+func (d *consulServiceDiscovery) Discover(service string) ([]VaultEndpoint, error) {
+	consulConfig := consulapi.DefaultConfig()
+
+	consulClient, err := consulapi.NewClient(consulConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Consul client: %v", err)
+	}
+
+	services, _, err := consulClient.Catalog().Service(service, "", &consulapi.QueryOptions{AllowStale: true})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]VaultEndpoint, 0, len(services))
+	for _, svc := range services {
+		endpoints = append(endpoints, VaultEndpoint{
+			Address: fmt.Sprintf("%s://%s:%d", consulConfig.Scheme, svc.ServiceAddress, svc.ServicePort),
+		})
+	}
+	return endpoints, nil
+}
+
+// dnsServiceDiscovery discovers Vault nodes via DNS SRV records named
+// _<service>._tcp.<DNS_DOMAIN>, e.g. "_vault._tcp.service.consul" style lookups
+// against a plain DNS server. The domain to query under is taken from the
+// DNS_DOMAIN environment variable, and the scheme used to reach each
+// discovered node from DNS_SCHEME (default "http", since an SRV record
+// says nothing about whether the node terminates TLS). Set DNS_SCHEME=https
+// rather than relying on "-insecure" to override a scheme this backend
+// hasn't actually verified.
+type dnsServiceDiscovery struct{}
+
+func (d *dnsServiceDiscovery) Discover(service string) ([]VaultEndpoint, error) {
+	domain := os.Getenv("DNS_DOMAIN")
+	if domain == "" {
+		return nil, fmt.Errorf("DNS_DOMAIN must be set to use -auto-provider=dns")
+	}
+
+	_, addrs, err := net.LookupSRV(service, "tcp", domain)
+	if err != nil {
+		return nil, fmt.Errorf("SRV lookup for %s.%s failed: %v", service, domain, err)
+	}
+
+	scheme := os.Getenv("DNS_SCHEME")
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	endpoints := make([]VaultEndpoint, 0, len(addrs))
+	for _, addr := range addrs {
+		endpoints = append(endpoints, VaultEndpoint{
+			Address: fmt.Sprintf("%s://%s:%d", scheme, strings.TrimSuffix(addr.Target, "."), addr.Port),
+		})
+	}
+	return endpoints, nil
+}
+
+// k8sServiceDiscovery discovers Vault nodes via the Endpoints object backing
+// a Kubernetes Service. It uses in-cluster config when available, falling
+// back to the kubeconfig on disk (respecting KUBECONFIG), and looks up the
+// service in KUBE_NAMESPACE (default "default"). The scheme used to reach
+// each discovered pod IP comes from KUBE_SCHEME (default "http"): the
+// Endpoints object doesn't say whether the pod terminates TLS, so this
+// backend doesn't guess "https" and silently defeat "-insecure".
+type k8sServiceDiscovery struct{}
+
+func (d *k8sServiceDiscovery) Discover(service string) ([]VaultEndpoint, error) {
+	namespace := os.Getenv("KUBE_NAMESPACE")
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	scheme := os.Getenv("KUBE_SCHEME")
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	config, err := k8srest.InClusterConfig()
+	if err != nil {
+		loadingRules := k8sclientcmd.NewDefaultClientConfigLoadingRules()
+		config, err = k8sclientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			loadingRules, &k8sclientcmd.ConfigOverrides{}).ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Kubernetes config: %v", err)
+		}
+	}
+
+	clientset, err := k8s.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+
+	endpoints, err := clientset.CoreV1().Endpoints(namespace).Get(context.Background(), service, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up endpoints for service %s/%s: %v", namespace, service, err)
+	}
+
+	var out []VaultEndpoint
+	for _, subset := range endpoints.Subsets {
+		for _, port := range subset.Ports {
+			for _, addr := range subset.Addresses {
+				out = append(out, VaultEndpoint{
+					Address: fmt.Sprintf("%s://%s:%d", scheme, addr.IP, port.Port),
+				})
+			}
+		}
+	}
+	return out, nil
+}
+
+// etcdServiceDiscovery discovers Vault nodes registered as keys under
+// /service/<service>/ in etcd, one member address per key, using the etcd
+// cluster listed in the ETCD_ENDPOINTS environment variable (comma-separated).
+type etcdServiceDiscovery struct{}
+
+func (d *etcdServiceDiscovery) Discover(service string) ([]VaultEndpoint, error) {
+	endpointList := os.Getenv("ETCD_ENDPOINTS")
+	if endpointList == "" {
+		return nil, fmt.Errorf("ETCD_ENDPOINTS must be set to use -auto-provider=etcd")
+	}
+
+	client, err := etcdclient.New(etcdclient.Config{
+		Endpoints: strings.Split(endpointList, ","),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd client: %v", err)
+	}
+
+	kapi := etcdclient.NewKeysAPI(client)
+	resp, err := kapi.Get(context.Background(), "/service/"+service, &etcdclient.GetOptions{Recursive: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list /service/%s in etcd: %v", service, err)
+	}
+
+	var out []VaultEndpoint
+	if resp.Node != nil {
+		for _, node := range resp.Node.Nodes {
+			out = append(out, VaultEndpoint{Address: node.Value})
+		}
+	}
+	return out, nil
+}
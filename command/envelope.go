@@ -0,0 +1,109 @@
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	uuid "github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/helper/pgpkeys"
+)
+
+// keyEnvelopeShare is a single PGP-encrypted unseal or recovery share
+// bundled into a keyEnvelope, along with enough metadata to identify which
+// operator's key can decrypt it.
+type keyEnvelopeShare struct {
+	Index            int    `json:"index"`
+	Threshold        int    `json:"threshold"`
+	Ciphertext       string `json:"ciphertext"`
+	CiphertextSHA256 string `json:"ciphertext_sha256"`
+	Fingerprint      string `json:"pgp_fingerprint,omitempty"`
+	KeybaseUser      string `json:"keybase_user,omitempty"`
+}
+
+// keyEnvelope is the portable, verifiable artifact written by
+// "-key-envelope" in place of unseal/recovery shares pasted into chat.
+type keyEnvelope struct {
+	Nonce          string             `json:"nonce"`
+	UnsealShares   []keyEnvelopeShare `json:"unseal_shares"`
+	RecoveryShares []keyEnvelopeShare `json:"recovery_shares,omitempty"`
+}
+
+// writeKeyEnvelope builds a keyEnvelope from a completed init and writes it
+// to path as JSON.
+func writeKeyEnvelope(path string, initRequest *api.InitRequest, resp *api.InitResponse) error {
+	nonce, err := uuid.GenerateUUID()
+	if err != nil {
+		return fmt.Errorf("error generating envelope nonce: %s", err)
+	}
+
+	unsealFingerprints, err := pgpkeys.GetFingerprints(initRequest.PGPKeys, nil)
+	if err != nil {
+		return fmt.Errorf("error calculating PGP key fingerprints: %s", err)
+	}
+	recoveryFingerprints, err := pgpkeys.GetFingerprints(initRequest.RecoveryPGPKeys, nil)
+	if err != nil {
+		return fmt.Errorf("error calculating PGP key fingerprints: %s", err)
+	}
+
+	envelope := keyEnvelope{
+		Nonce:          nonce,
+		UnsealShares:   buildKeyEnvelopeShares(resp.Keys, initRequest.SecretThreshold, initRequest.PGPKeys, unsealFingerprints),
+		RecoveryShares: buildKeyEnvelopeShares(resp.RecoveryKeys, initRequest.RecoveryThreshold, initRequest.RecoveryPGPKeys, recoveryFingerprints),
+	}
+
+	data, err := json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling key envelope: %s", err)
+	}
+
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("error writing key envelope to %s: %s", path, err)
+	}
+
+	return nil
+}
+
+// buildKeyEnvelopeShares pairs each ciphertext share with its threshold,
+// SHA-256, and (when the corresponding "-pgp-keys"/"-recovery-pgp-keys"
+// entry was a "keybase:<user>" reference) its Keybase username.
+func buildKeyEnvelopeShares(ciphertexts []string, threshold int, pgpKeyRefs []string, fingerprints []string) []keyEnvelopeShare {
+	shares := make([]keyEnvelopeShare, len(ciphertexts))
+	for i, ct := range ciphertexts {
+		hash := sha256.Sum256([]byte(ct))
+		share := keyEnvelopeShare{
+			Index:            i + 1,
+			Threshold:        threshold,
+			Ciphertext:       ct,
+			CiphertextSHA256: hex.EncodeToString(hash[:]),
+		}
+		if i < len(fingerprints) {
+			share.Fingerprint = fingerprints[i]
+		}
+		if i < len(pgpKeyRefs) && strings.HasPrefix(pgpKeyRefs[i], "keybase:") {
+			share.KeybaseUser = strings.TrimPrefix(pgpKeyRefs[i], "keybase:")
+		}
+		shares[i] = share
+	}
+	return shares
+}
+
+// readKeyEnvelope reads and parses a key envelope previously written by
+// "-key-envelope".
+func readKeyEnvelope(path string) (*keyEnvelope, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading key envelope %s: %s", path, err)
+	}
+
+	var envelope keyEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("error parsing key envelope %s: %s", path, err)
+	}
+
+	return &envelope, nil
+}
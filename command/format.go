@@ -0,0 +1,57 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// outputFormat is the machine- or human-readable format that a command's
+// "-format" flag selects.
+type outputFormat string
+
+const (
+	outputFormatTable outputFormat = "table"
+	outputFormatJSON  outputFormat = "json"
+	outputFormatYAML  outputFormat = "yaml"
+)
+
+// parseOutputFormat validates a "-format" flag value, defaulting to
+// outputFormatTable when raw is empty. It returns an error for any value
+// that isn't one of table, json, or yaml.
+func parseOutputFormat(raw string) (outputFormat, error) {
+	switch outputFormat(strings.ToLower(strings.TrimSpace(raw))) {
+	case "", outputFormatTable:
+		return outputFormatTable, nil
+	case outputFormatJSON:
+		return outputFormatJSON, nil
+	case outputFormatYAML:
+		return outputFormatYAML, nil
+	default:
+		return "", fmt.Errorf("invalid format: %q (valid formats: table, json, yaml)", raw)
+	}
+}
+
+// marshalOutputFormat encodes data as JSON or YAML for -format=json/yaml. It
+// is not used for outputFormatTable, since table output is rendered by hand
+// by each command.
+func marshalOutputFormat(format outputFormat, data interface{}) (string, error) {
+	switch format {
+	case outputFormatJSON:
+		b, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error marshaling to JSON: %s", err)
+		}
+		return string(b), nil
+	case outputFormatYAML:
+		b, err := yaml.Marshal(data)
+		if err != nil {
+			return "", fmt.Errorf("error marshaling to YAML: %s", err)
+		}
+		return strings.TrimSuffix(string(b), "\n"), nil
+	default:
+		return "", fmt.Errorf("unsupported format for marshaling: %s", format)
+	}
+}
@@ -1,12 +1,14 @@
 package command
 
 import (
+	"bufio"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
-	consulapi "github.com/hashicorp/consul/api"
 	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/helper/pgpkeys"
 	"github.com/hashicorp/vault/meta"
@@ -15,6 +17,21 @@ import (
 // InitCommand is a Command that initializes a new Vault server.
 type InitCommand struct {
 	meta.Meta
+
+	// format is set by Run from the "-format" flag and consulted by
+	// runInit and checkStatus to decide how to render their output.
+	format outputFormat
+
+	// keyEnvelopePath is set by Run from the "-key-envelope" flag. When
+	// non-empty, runInit writes the generated shares to this path as a key
+	// envelope in addition to the normal output.
+	keyEnvelopePath string
+
+	// retryAttempts and retryMaxInterval are set by Run from
+	// "-retry-attempts" and "-retry-max-interval" and govern how the
+	// InitStatus/Init/discovery RPCs below are retried.
+	retryAttempts    int
+	retryMaxInterval time.Duration
 }
 
 func (c *InitCommand) Run(args []string) int {
@@ -22,6 +39,15 @@ func (c *InitCommand) Run(args []string) int {
 	var pgpKeys, recoveryPgpKeys pgpkeys.PubKeyFilesFlag
 	var check bool
 	var auto string
+	var autoProvider string
+	var format string
+	var autoUnseal bool
+	var unsealKeysFile string
+	var unsealPgpKeyring string
+	var insecure bool
+	var keyEnvelopePath string
+	var retryAttempts int
+	var retryMaxIntervalRaw string
 	flags := c.Meta.FlagSet("init", meta.FlagSetDefault)
 	flags.Usage = func() { c.Ui.Error(c.Help()) }
 	flags.IntVar(&shares, "key-shares", 5, "")
@@ -33,10 +59,40 @@ func (c *InitCommand) Run(args []string) int {
 	flags.Var(&recoveryPgpKeys, "recovery-pgp-keys", "")
 	flags.BoolVar(&check, "check", false, "")
 	flags.StringVar(&auto, "auto", "", "")
+	flags.StringVar(&autoProvider, "auto-provider", "consul", "")
+	flags.StringVar(&format, "format", "table", "")
+	flags.BoolVar(&autoUnseal, "auto-unseal", false, "")
+	flags.StringVar(&unsealKeysFile, "auto-unseal-keys-file", "", "")
+	flags.StringVar(&unsealPgpKeyring, "auto-unseal-pgp-keyring", "", "")
+	flags.BoolVar(&insecure, "insecure", false, "")
+	flags.StringVar(&keyEnvelopePath, "key-envelope", "", "")
+	flags.IntVar(&retryAttempts, "retry-attempts", 1, "")
+	flags.StringVar(&retryMaxIntervalRaw, "retry-max-interval", "30s", "")
 	if err := flags.Parse(args); err != nil {
 		return 1
 	}
 
+	if autoUnseal && auto == "" {
+		c.Ui.Error("-auto-unseal can only be used together with -auto")
+		return 1
+	}
+
+	retryMaxInterval, err := time.ParseDuration(retryMaxIntervalRaw)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Invalid -retry-max-interval: %s", err))
+		return 1
+	}
+	c.retryAttempts = retryAttempts
+	c.retryMaxInterval = retryMaxInterval
+
+	outFormat, err := parseOutputFormat(format)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	c.format = outFormat
+	c.keyEnvelopePath = keyEnvelopePath
+
 	initRequest := &api.InitRequest{
 		SecretShares:      shares,
 		SecretThreshold:   threshold,
@@ -47,53 +103,67 @@ func (c *InitCommand) Run(args []string) int {
 		RecoveryPGPKeys:   recoveryPgpKeys,
 	}
 
-	// If running in 'auto' mode, run service discovery based on environment
-	// variables of Consul.
+	// If running in 'auto' mode, run service discovery against the backend
+	// selected by "-auto-provider" (Consul, DNS, Kubernetes, or etcd).
 	if auto != "" {
-		// Create configuration for Consul
-		consulConfig := consulapi.DefaultConfig()
-
-		// Create a client to communicate with Consul
-		consulClient, err := consulapi.NewClient(consulConfig)
+		discovery, err := newServiceDiscovery(autoProvider)
 		if err != nil {
-			c.Ui.Error(fmt.Sprintf("failed to create Consul client:%v", err))
+			c.Ui.Error(err.Error())
 			return 1
 		}
 
 		var uninitializedVaults []string
 		var initializedVault string
+		var allDiscoveredVaults []string
+
+		// Query the nodes belonging to the cluster, retrying recoverable
+		// failures (e.g. a stale Consul catalog read during rolling
+		// bring-up) up to "-retry-attempts" times.
+		var endpoints []VaultEndpoint
+		discoverErr := withRetry(c.retryAttempts, c.retryMaxInterval, func() error {
+			var derr error
+			endpoints, derr = discovery.Discover(auto)
+			return derr
+		})
+		if discoverErr != nil {
+			c.Ui.Error(fmt.Sprintf("Error discovering Vault nodes under the service name '%s': %s", auto, discoverErr))
+			return 1
+		}
 
-		// Query the nodes belonging to the cluster
-		if services, _, err := consulClient.Catalog().Service(auto, "", &consulapi.QueryOptions{AllowStale: true}); err == nil {
-		Loop:
-			for _, service := range services {
-				vaultAddress := fmt.Sprintf("%s://%s:%d", consulConfig.Scheme, service.ServiceAddress, service.ServicePort)
-
-				// Set VAULT_ADDR to the discovered node
-				os.Setenv(api.EnvVaultAddress, vaultAddress)
-
-				// Create a client to communicate with the discovered node
-				client, err := c.Client()
-				if err != nil {
-					c.Ui.Error(fmt.Sprintf(
-						"Error initializing client: %s", err))
-					return 1
-				}
+	Loop:
+		for _, endpoint := range endpoints {
+			vaultAddress := endpoint.Address
+			allDiscoveredVaults = append(allDiscoveredVaults, vaultAddress)
 
-				// Check the initialization status of the discovered node
-				inited, err := client.Sys().InitStatus()
-				switch {
-				case err != nil:
-					c.Ui.Error(fmt.Sprintf("Error checking initialization status of discovered node: %s err:%s", vaultAddress, err))
-					return 1
-				case inited:
-					// One of the nodes in the cluster is initialized. Break out.
-					initializedVault = vaultAddress
-					break Loop
-				default:
-					// Vault is uninitialized.
-					uninitializedVaults = append(uninitializedVaults, vaultAddress)
-				}
+			// Set VAULT_ADDR to the discovered node
+			os.Setenv(api.EnvVaultAddress, vaultAddress)
+
+			// Create a client to communicate with the discovered node
+			client, err := c.Client()
+			if err != nil {
+				c.Ui.Error(fmt.Sprintf(
+					"Error initializing client: %s", err))
+				return 1
+			}
+
+			// Check the initialization status of the discovered node
+			var inited bool
+			err = withRetry(c.retryAttempts, c.retryMaxInterval, func() error {
+				var ierr error
+				inited, ierr = client.Sys().InitStatus()
+				return ierr
+			})
+			switch {
+			case err != nil:
+				c.Ui.Error(fmt.Sprintf("Error checking initialization status of discovered node: %s err:%s", vaultAddress, err))
+				return 1
+			case inited:
+				// One of the nodes in the cluster is initialized. Break out.
+				initializedVault = vaultAddress
+				break Loop
+			default:
+				// Vault is uninitialized.
+				uninitializedVaults = append(uninitializedVaults, vaultAddress)
 			}
 		}
 
@@ -111,35 +181,57 @@ func (c *InitCommand) Run(args []string) int {
 			return 0
 		}
 
-		switch len(uninitializedVaults) {
-		case 0:
+		switch {
+		case len(uninitializedVaults) == 0:
 			c.Ui.Error(fmt.Sprintf("Failed to discover Vault nodes under the service name '%s'", auto))
 			return 1
-		case 1:
-			// There was only one node found in the Vault cluster and it
-			// was uninitialized.
+		case len(uninitializedVaults) == 1 || autoUnseal:
+			// Either there was only one uninitialized node in the cluster,
+			// or there were several and "-auto-unseal" was given: in both
+			// cases we initialize the first uninitialized node and, when
+			// "-auto-unseal" is set, submit the resulting keys to every
+			// discovered node (the freshly initialized one and any other
+			// nodes sharing its storage backend) until each reaches
+			// quorum. This is what closes the gap where "-auto" init
+			// otherwise leaves the operator to loop "vault unseal" by hand
+			// against every node of a multi-node cluster.
+			target := uninitializedVaults[0]
+
+			if len(uninitializedVaults) > 1 {
+				c.Ui.Output(fmt.Sprintf(
+					"Discovered %d uninitialized Vault nodes under the service name '%s'; initializing '%s' and auto-unsealing the rest\n",
+					len(uninitializedVaults), auto, target))
+			} else {
+				c.Ui.Output(fmt.Sprintf("Discovered Vault at '%s'\n", target))
+			}
 
 			// Set the VAULT_ADDR to the discovered node. This will ensure
 			// that the client created will operate on the discovered node.
-			os.Setenv(api.EnvVaultAddress, uninitializedVaults[0])
-
-			// Let the client know that initialization is perfomed on the
-			// discovered node.
-			c.Ui.Output(fmt.Sprintf("Discovered Vault at '%s'\n", uninitializedVaults[0]))
+			os.Setenv(api.EnvVaultAddress, target)
 
 			// Attempt initializing it
-			ret := c.runInit(check, initRequest)
+			resp, ret := c.runInit(check, initRequest)
+
+			if ret == 0 && !check && autoUnseal {
+				if unsealRet := c.autoUnsealCluster(resp, initRequest, allDiscoveredVaults, unsealKeysFile, unsealPgpKeyring, insecure); unsealRet != 0 {
+					ret = unsealRet
+				}
+			}
 
 			// Regardless of success or failure, instruct client to update VAULT_ADDR
 			c.Ui.Output("Set the following environment variable to operate on the discovered Vault:\n")
-			c.Ui.Output(fmt.Sprintf("\t%s VAULT_ADDR=%shttp://%s%s", export, quote, uninitializedVaults[0], quote))
+			c.Ui.Output(fmt.Sprintf("\t%s VAULT_ADDR=%shttp://%s%s", export, quote, target, quote))
 
 			return ret
 		default:
-			// If more than one Vault node were discovered, print out all of them,
-			// requiring the client to update VAULT_ADDR and to run init again.
+			// More than one uninitialized Vault node was discovered and
+			// "-auto-unseal" was not given, so there's no way to unseal
+			// the rest of the cluster automatically after initializing
+			// one of them. Print them all out, requiring the operator to
+			// pick one, update VAULT_ADDR, and run init again (optionally
+			// with "-auto-unseal" this time).
 			c.Ui.Output(fmt.Sprintf("Discovered more than one uninitialized Vaults under the service name '%s'\n", auto))
-			c.Ui.Output("To initialize all Vaults, set any *one* of the following and run 'vault init':")
+			c.Ui.Output("To initialize all Vaults, set any *one* of the following and run 'vault init' (add -auto-unseal to also unseal the rest automatically):")
 
 			// Print valid commands to make setting the variables easier
 			for _, vaultNode := range uninitializedVaults {
@@ -150,26 +242,61 @@ func (c *InitCommand) Run(args []string) int {
 		}
 	}
 
-	return c.runInit(check, initRequest)
+	_, ret := c.runInit(check, initRequest)
+	return ret
 }
 
-func (c *InitCommand) runInit(check bool, initRequest *api.InitRequest) int {
+// runInit performs the actual init (or, if check is set, status check)
+// against the currently configured client. It returns the raw InitResponse
+// so callers such as the auto-unseal orchestration can act on the freshly
+// generated keys; resp is nil for -check or when the client or the Init
+// call fails, and is still returned alongside a non-zero code when only
+// writing the key envelope or rendering the output fails.
+func (c *InitCommand) runInit(check bool, initRequest *api.InitRequest) (*api.InitResponse, int) {
 	client, err := c.Client()
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf(
 			"Error initializing client: %s", err))
-		return 1
+		return nil, 1
 	}
 
 	if check {
-		return c.checkStatus(client)
+		return nil, c.checkStatus(client)
 	}
 
-	resp, err := client.Sys().Init(initRequest)
+	var resp *api.InitResponse
+	err = withRetry(c.retryAttempts, c.retryMaxInterval, func() error {
+		var ierr error
+		resp, ierr = client.Sys().Init(initRequest)
+		return ierr
+	})
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf(
 			"Error initializing Vault: %s", err))
-		return 1
+		return nil, 1
+	}
+
+	// A failure writing the key envelope must never suppress the normal
+	// output below: resp.Keys/RecoveryKeys/RootToken are the only copy of
+	// these one-time secrets now that Init has already run against the
+	// server, so the operator has to see them printed even if the envelope
+	// file couldn't be written.
+	var envelopeErr error
+	if c.keyEnvelopePath != "" {
+		if err := writeKeyEnvelope(c.keyEnvelopePath, initRequest, resp); err != nil {
+			envelopeErr = err
+		} else {
+			c.Ui.Output(fmt.Sprintf("Key envelope written to %s", c.keyEnvelopePath))
+		}
+	}
+
+	if c.format != outputFormatTable {
+		ret := c.outputInitResponse(initRequest, resp)
+		if envelopeErr != nil {
+			c.Ui.Error(fmt.Sprintf("Error writing key envelope: %s", envelopeErr))
+			return resp, 1
+		}
+		return resp, ret
 	}
 
 	for i, key := range resp.Keys {
@@ -211,11 +338,102 @@ func (c *InitCommand) runInit(check bool, initRequest *api.InitRequest) int {
 		))
 	}
 
+	if envelopeErr != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing key envelope: %s", envelopeErr))
+		return resp, 1
+	}
+
+	return resp, 0
+}
+
+// initKeyOutput describes a single unseal or recovery share in structured
+// -format=json/yaml output.
+type initKeyOutput struct {
+	Index       int    `json:"index" yaml:"index"`
+	Value       string `json:"value" yaml:"value"`
+	Fingerprint string `json:"pgp_fingerprint,omitempty" yaml:"pgp_fingerprint,omitempty"`
+}
+
+// initResponseOutput is the structured document emitted by -format=json and
+// -format=yaml for a successful init, mirroring api.InitResponse.
+type initResponseOutput struct {
+	UnsealKeys        []initKeyOutput `json:"unseal_keys" yaml:"unseal_keys"`
+	RecoveryKeys      []initKeyOutput `json:"recovery_keys,omitempty" yaml:"recovery_keys,omitempty"`
+	RootToken         string          `json:"root_token" yaml:"root_token"`
+	SecretShares      int             `json:"secret_shares" yaml:"secret_shares"`
+	SecretThreshold   int             `json:"secret_threshold" yaml:"secret_threshold"`
+	StoredShares      int             `json:"stored_shares" yaml:"stored_shares"`
+	RecoveryShares    int             `json:"recovery_shares,omitempty" yaml:"recovery_shares,omitempty"`
+	RecoveryThreshold int             `json:"recovery_threshold,omitempty" yaml:"recovery_threshold,omitempty"`
+}
+
+// outputInitResponse renders resp as JSON or YAML per c.format, attaching
+// the PGP fingerprint of the encrypting key to each share when PGP keys were
+// supplied for that share set.
+func (c *InitCommand) outputInitResponse(initRequest *api.InitRequest, resp *api.InitResponse) int {
+	unsealFingerprints, err := pgpkeys.GetFingerprints(initRequest.PGPKeys, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error calculating PGP key fingerprints: %s", err))
+		return 1
+	}
+	recoveryFingerprints, err := pgpkeys.GetFingerprints(initRequest.RecoveryPGPKeys, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error calculating PGP key fingerprints: %s", err))
+		return 1
+	}
+
+	out := initResponseOutput{
+		UnsealKeys:        buildInitKeyOutput(resp.Keys, unsealFingerprints),
+		RecoveryKeys:      buildInitKeyOutput(resp.RecoveryKeys, recoveryFingerprints),
+		RootToken:         resp.RootToken,
+		SecretShares:      initRequest.SecretShares,
+		SecretThreshold:   initRequest.SecretThreshold,
+		StoredShares:      initRequest.StoredShares,
+		RecoveryShares:    initRequest.RecoveryShares,
+		RecoveryThreshold: initRequest.RecoveryThreshold,
+	}
+
+	rendered, err := marshalOutputFormat(c.format, out)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	c.Ui.Output(rendered)
 	return 0
 }
 
+// buildInitKeyOutput pairs each share value with its encrypting key's
+// fingerprint, if any were supplied.
+func buildInitKeyOutput(values []string, fingerprints []string) []initKeyOutput {
+	out := make([]initKeyOutput, len(values))
+	for i, v := range values {
+		out[i] = initKeyOutput{Index: i + 1, Value: v}
+		if i < len(fingerprints) {
+			out[i].Fingerprint = fingerprints[i]
+		}
+	}
+	return out
+}
+
 func (c *InitCommand) checkStatus(client *api.Client) int {
-	inited, err := client.Sys().InitStatus()
+	var inited bool
+	err := withRetry(c.retryAttempts, c.retryMaxInterval, func() error {
+		var ierr error
+		inited, ierr = client.Sys().InitStatus()
+		return ierr
+	})
+	if c.format != outputFormatTable && err == nil {
+		rendered, ferr := marshalOutputFormat(c.format, map[string]bool{"initialized": inited})
+		if ferr != nil {
+			c.Ui.Error(ferr.Error())
+			return 1
+		}
+		c.Ui.Output(rendered)
+		if inited {
+			return 0
+		}
+		return 2
+	}
 	switch {
 	case err != nil:
 		c.Ui.Error(fmt.Sprintf(
@@ -230,6 +448,112 @@ func (c *InitCommand) checkStatus(client *api.Client) int {
 	}
 }
 
+// autoUnsealCluster gathers plaintext unseal keys (either the shares
+// api.Init just generated, decrypted PGP shares from a keyring, or shares
+// read from a file/stdin) and submits them to every node discovered via
+// the "-auto-provider" backend until each reaches quorum, closing the gap
+// where "-auto" init
+// otherwise leaves the operator to manually loop "vault unseal" against
+// every node.
+func (c *InitCommand) autoUnsealCluster(resp *api.InitResponse, initRequest *api.InitRequest, vaultAddrs []string, keysFile, pgpKeyring string, insecure bool) int {
+	keys, err := c.gatherUnsealKeys(resp, initRequest, keysFile, pgpKeyring)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error gathering unseal keys: %s", err))
+		return 1
+	}
+
+	for _, addr := range vaultAddrs {
+		if !insecure && strings.HasPrefix(addr, "http://") {
+			c.Ui.Error(fmt.Sprintf(
+				"Refusing to send unseal keys to %s over plaintext HTTP; pass -insecure to override", addr))
+			return 1
+		}
+
+		if err := c.unsealNode(addr, keys); err != nil {
+			c.Ui.Error(fmt.Sprintf("Error unsealing %s: %s", addr, err))
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// gatherUnsealKeys returns the plaintext unseal keys to submit during
+// auto-unseal, preferring (in order): an explicit -auto-unseal-keys-file (or
+// stdin, via "-"), PGP-decrypted shares from resp when -pgp-keys and
+// -auto-unseal-pgp-keyring were both used, or the plaintext shares api.Init
+// just returned.
+func (c *InitCommand) gatherUnsealKeys(resp *api.InitResponse, initRequest *api.InitRequest, keysFile, pgpKeyring string) ([]string, error) {
+	if keysFile != "" {
+		return readUnsealKeysFile(keysFile)
+	}
+
+	if pgpKeyring != "" && len(initRequest.PGPKeys) > 0 {
+		return decryptPGPShares(resp.Keys, pgpKeyring)
+	}
+
+	if pgpKeyring == "" && len(initRequest.PGPKeys) > 0 {
+		return nil, fmt.Errorf(
+			"-pgp-keys was used, so the generated shares are PGP ciphertext, not usable as unseal keys; " +
+				"supply -auto-unseal-pgp-keyring to decrypt them for submission")
+	}
+
+	return resp.Keys, nil
+}
+
+// readUnsealKeysFile reads newline-separated unseal keys from path, or from
+// stdin when path is "-".
+func readUnsealKeysFile(path string) ([]string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(bufio.NewReader(os.Stdin))
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			keys = append(keys, line)
+		}
+	}
+	return keys, nil
+}
+
+// unsealNode submits keys to addr one at a time until it reports unsealed,
+// printing seal-status progress after each submission. An ErrInvalidKey is
+// logged and skipped rather than aborting the node.
+func (c *InitCommand) unsealNode(addr string, keys []string) error {
+	os.Setenv(api.EnvVaultAddress, addr)
+	client, err := c.Client()
+	if err != nil {
+		return fmt.Errorf("error initializing client: %s", err)
+	}
+
+	for _, key := range keys {
+		status, err := client.Sys().Unseal(strings.TrimSpace(key))
+		switch {
+		case err == api.ErrInvalidKey:
+			c.Ui.Output(fmt.Sprintf("%s: invalid key, skipping", addr))
+			continue
+		case err != nil:
+			return err
+		}
+
+		c.Ui.Output(fmt.Sprintf("%s: sealed=%v progress=%d/%d", addr, status.Sealed, status.Progress, status.T))
+		if !status.Sealed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("ran out of keys before %s reported unsealed", addr)
+}
+
 func (c *InitCommand) Synopsis() string {
 	return "Initialize a new Vault server"
 }
@@ -256,6 +580,13 @@ Init Options:
                             initialized; a return code of 1 means an error was
                             encountered.
 
+  -format=table             The format for output. By default it is a
+                            human-readable table. Setting this to "json" or
+                            "yaml" prints the same information (unseal keys,
+                            recovery keys, and root token) as a structured
+                            document instead, for consumption by scripts and
+                            config-loading tools. Also honored by "-check".
+
   -key-shares=5             The number of key shares to split the master key
                             into.
 
@@ -283,6 +614,67 @@ Init Options:
 
   -recovery-pgp-keys        If provided, behaves like "pgp-keys" but for the
                             recovery key shares. This is not normally available.
+
+  -key-envelope=<path>      Write each generated unseal/recovery share (after
+                            any "-pgp-keys"/"-recovery-pgp-keys" encryption)
+                            into a single JSON "key envelope" file at path,
+                            alongside the normal output. Each share carries
+                            its index, threshold, ciphertext, the SHA-256 of
+                            the ciphertext, the encrypting key's fingerprint,
+                            and its Keybase username if "keybase:<user>" was
+                            used. Read back with
+                            "vault operator unseal -envelope=<path>".
+
+  -retry-attempts=1         Number of times to attempt each InitStatus/Init
+                            call and, under "-auto", each discovery lookup,
+                            before giving up. Network timeouts, 5xx
+                            responses, and Consul stale-read misses are
+                            retried with exponential backoff; a 4xx response
+                            or an already-initialized Vault aborts
+                            immediately regardless of this setting.
+
+  -retry-max-interval=30s   Upper bound on the exponential backoff between
+                            retries when "-retry-attempts" is greater than 1.
+
+  -auto-provider=consul     The service-discovery backend "-auto" uses to
+                            find the Vault cluster's nodes. One of "consul",
+                            "dns", "k8s", or "etcd". Each backend reads its
+                            own connection details from the environment:
+                              consul - CONSUL_HTTP_ADDR, CONSUL_HTTP_TOKEN
+                              dns    - DNS_DOMAIN, the domain SRV records for
+                                       the service are looked up under, and
+                                       DNS_SCHEME (default "http", since an
+                                       SRV record doesn't say whether a node
+                                       terminates TLS)
+                              k8s    - KUBE_NAMESPACE (default "default");
+                                       uses in-cluster config when running
+                                       inside a pod, otherwise KUBECONFIG;
+                                       and KUBE_SCHEME (default "http", for
+                                       the same reason as DNS_SCHEME above)
+                              etcd   - ETCD_ENDPOINTS, a comma-separated list
+                                       of cluster member URLs
+
+  -auto-unseal              Only valid with "-auto". After the discovered
+                            uninitialized node (the first one, when several
+                            are discovered) is initialized,
+                            submit unseal keys to every Vault node discovered
+                            under the same service name until each reaches
+                            quorum, instead of leaving the operator to run
+                            "vault unseal" against every node by hand.
+
+  -auto-unseal-keys-file=   The unseal keys to submit during "-auto-unseal",
+                            one per line. Use "-" to read from stdin. If
+                            unset, the plaintext shares this command just
+                            generated are used.
+
+  -auto-unseal-pgp-keyring= Path to a local GPG private keyring used to
+                            decrypt the freshly generated shares before
+                            submitting them, when "-pgp-keys" was also given.
+
+  -insecure                 Allow "-auto-unseal" to submit plaintext unseal
+                            keys to a node whose discovered address uses the
+                            "http" scheme. Without this flag, such a node
+                            aborts the unseal loop.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -0,0 +1,139 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/meta"
+)
+
+// OperatorUnsealCommand is a Command that provides a single unseal key
+// share to a Vault server, either directly on the command line or, via
+// "-envelope", read out of a key envelope written by "vault init
+// -key-envelope".
+type OperatorUnsealCommand struct {
+	meta.Meta
+}
+
+func (c *OperatorUnsealCommand) Run(args []string) int {
+	var envelopePath string
+	var keyring string
+	var reset bool
+	flags := c.Meta.FlagSet("operator unseal", meta.FlagSetDefault)
+	flags.Usage = func() { c.Ui.Error(c.Help()) }
+	flags.StringVar(&envelopePath, "envelope", "", "")
+	flags.StringVar(&keyring, "keyring", "", "")
+	flags.BoolVar(&reset, "reset", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+	args = flags.Args()
+
+	client, err := c.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	if reset {
+		status, err := client.Sys().ResetUnsealProcess()
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error resetting unseal process: %s", err))
+			return 1
+		}
+		return c.outputSealStatus(status)
+	}
+
+	var key string
+	switch {
+	case envelopePath != "":
+		key, err = c.keyFromEnvelope(envelopePath, keyring)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+	case len(args) > 0:
+		key = strings.TrimSpace(args[0])
+	default:
+		c.Ui.Error("Must supply an unseal key, or -envelope=<path>")
+		return 1
+	}
+
+	status, err := client.Sys().Unseal(key)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error unsealing: %s", err))
+		return 1
+	}
+
+	return c.outputSealStatus(status)
+}
+
+// keyFromEnvelope reads a key envelope written by "vault init
+// -key-envelope" and decrypts the first unseal share whose ciphertext the
+// local operator's GPG key (loaded from keyring, or the default keyring
+// when empty) can open, so the operator never has to eyeball which of the
+// listed shares is theirs.
+func (c *OperatorUnsealCommand) keyFromEnvelope(path, keyring string) (string, error) {
+	envelope, err := readKeyEnvelope(path)
+	if err != nil {
+		return "", err
+	}
+
+	for _, share := range envelope.UnsealShares {
+		plaintexts, err := decryptPGPShares([]string{share.Ciphertext}, keyring)
+		if err != nil || len(plaintexts) != 1 {
+			// Not encrypted to a key we hold; move on to the next share.
+			continue
+		}
+		return plaintexts[0], nil
+	}
+
+	return "", fmt.Errorf("no share in %s could be decrypted with the local operator's key", path)
+}
+
+func (c *OperatorUnsealCommand) outputSealStatus(status *api.SealStatusResponse) int {
+	c.Ui.Output(fmt.Sprintf("Sealed: %v", status.Sealed))
+	c.Ui.Output(fmt.Sprintf("Key Shares: %d", status.N))
+	c.Ui.Output(fmt.Sprintf("Key Threshold: %d", status.T))
+	c.Ui.Output(fmt.Sprintf("Unseal Progress: %d", status.Progress))
+	if status.Sealed {
+		return 2
+	}
+	return 0
+}
+
+func (c *OperatorUnsealCommand) Synopsis() string {
+	return "Unseals the Vault server"
+}
+
+func (c *OperatorUnsealCommand) Help() string {
+	helpText := `
+Usage: vault operator unseal [options] [key]
+
+  Provide a portion of the master key to unseal a Vault server.
+
+  Vault starts sealed. Before any operation can be performed on Vault, it
+  must be unsealed. This command allows you to enter a portion of the
+  master key needed to unseal Vault, either directly as an argument or,
+  via "-envelope", read out of a key envelope written by
+  "vault init -key-envelope".
+
+General Options:
+` + meta.GeneralOptionsUsage() + `
+Unseal Options:
+
+  -envelope=<path>          Read the unseal share to submit from a key
+                             envelope at path, decrypting it with the local
+                             operator's GPG key rather than requiring the
+                             plaintext share as an argument.
+
+  -keyring=<path>           GPG private keyring to decrypt the envelope
+                             share with, when "-envelope" is used. Defaults
+                             to ~/.gnupg/secring.gpg.
+
+  -reset                    Reset the unseal process by throwing away
+                             previously entered shares.
+`
+	return strings.TrimSpace(helpText)
+}
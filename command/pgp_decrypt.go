@@ -0,0 +1,60 @@
+package command
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+// decryptPGPShares decrypts hex-encoded, PGP-encrypted unseal/recovery
+// shares (as emitted by "vault init -pgp-keys"/"-recovery-pgp-keys") using a
+// local private keyring. There's no pre-existing helper for this in
+// helper/pgpkeys, which only deals with public keys on the encrypt side, so
+// this lives here next to the commands that need it. When keyringPath is
+// empty, the default GnuPG secret keyring is used.
+func decryptPGPShares(ciphertexts []string, keyringPath string) ([]string, error) {
+	if keyringPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("no keyring given and could not determine home directory: %s", err)
+		}
+		keyringPath = home + "/.gnupg/secring.gpg"
+	}
+
+	keyringFile, err := os.Open(keyringPath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening PGP keyring %s: %s", keyringPath, err)
+	}
+	defer keyringFile.Close()
+
+	entityList, err := openpgp.ReadKeyRing(keyringFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading PGP keyring %s: %s", keyringPath, err)
+	}
+
+	plaintexts := make([]string, len(ciphertexts))
+	for i, ct := range ciphertexts {
+		raw, err := hex.DecodeString(ct)
+		if err != nil {
+			return nil, fmt.Errorf("error hex-decoding share %d: %s", i+1, err)
+		}
+
+		md, err := openpgp.ReadMessage(bytes.NewReader(raw), entityList, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error decrypting share %d: %s", i+1, err)
+		}
+
+		body, err := ioutil.ReadAll(md.UnverifiedBody)
+		if err != nil {
+			return nil, fmt.Errorf("error reading decrypted share %d: %s", i+1, err)
+		}
+
+		plaintexts[i] = string(body)
+	}
+
+	return plaintexts, nil
+}
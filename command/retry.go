@@ -0,0 +1,107 @@
+package command
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+)
+
+// recoverabler is implemented by errors that can report whether the
+// operation that produced them is worth retrying.
+type recoverabler interface {
+	Recoverable() bool
+}
+
+// recoverableError wraps an error that a caller should retry rather than
+// abort on, e.g. a network timeout or a Consul stale-read miss during
+// rolling cluster bring-up.
+type recoverableError struct {
+	err error
+}
+
+func newRecoverableError(err error) *recoverableError {
+	return &recoverableError{err: errwrap.Wrapf("recoverable error: {{err}}", err)}
+}
+
+func (e *recoverableError) Error() string     { return e.err.Error() }
+func (e *recoverableError) Recoverable() bool { return true }
+func (e *recoverableError) WrappedErrors() []error {
+	return []error{e.err}
+}
+
+// unrecoverablePatterns match error text that should abort a retry loop
+// immediately: permanent 4xx responses and an already-initialized Vault, as
+// opposed to a node still starting up or a stale Consul catalog entry.
+var unrecoverablePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)already initialized`),
+	regexp.MustCompile(`(?i)\b400\b`),
+	regexp.MustCompile(`(?i)\b401\b`),
+	regexp.MustCompile(`(?i)\b403\b`),
+	regexp.MustCompile(`(?i)\b404\b`),
+	regexp.MustCompile(`(?i)\bcode:\s*4\d\d\b`),
+	regexp.MustCompile(`(?i)permission denied`),
+}
+
+// classifyError decides whether err should be retried. Errors that already
+// implement recoverabler are trusted as-is; everything else is checked
+// against unrecoverablePatterns and, absent a match, wrapped as
+// recoverable so network timeouts, 5xx responses, and Consul stale reads
+// get retried.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(recoverabler); ok {
+		return err
+	}
+
+	msg := err.Error()
+	for _, pattern := range unrecoverablePatterns {
+		if pattern.MatchString(msg) {
+			return err
+		}
+	}
+	return newRecoverableError(err)
+}
+
+func isRecoverable(err error) bool {
+	r, ok := err.(recoverabler)
+	return ok && r.Recoverable()
+}
+
+// withRetry calls fn until it succeeds, returns an error classifyError
+// deems unrecoverable, or attempts is exhausted, sleeping with exponential
+// backoff (capped at maxInterval) between tries. attempts <= 1 disables
+// retrying entirely.
+func withRetry(attempts int, maxInterval time.Duration, fn func() error) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	interval := 500 * time.Millisecond
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+
+	var lastErr error
+	for i := 0; i < attempts; i++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		lastErr = classifyError(err)
+		if !isRecoverable(lastErr) || i == attempts-1 {
+			return lastErr
+		}
+
+		time.Sleep(interval)
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+
+	return lastErr
+}
@@ -0,0 +1,132 @@
+package command
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		name        string
+		err         error
+		recoverable bool
+	}{
+		{"already initialized", errors.New("Vault is already initialized"), false},
+		{"400 bad request", errors.New("Error making API request. Code: 400"), false},
+		{"401 unauthorized", errors.New("Error making API request. Code: 401"), false},
+		{"403 forbidden", errors.New("403 Forbidden"), false},
+		{"404 not found", errors.New("404 not found"), false},
+		{"permission denied", errors.New("permission denied"), false},
+		{"generic timeout", errors.New("dial tcp: i/o timeout"), true},
+		{"5xx error", errors.New("Error making API request. Code: 503"), true},
+		{"consul stale read", errors.New("stale read from consul catalog"), true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := classifyError(tc.err)
+			if got == nil {
+				t.Fatalf("classifyError(%q) = nil, want non-nil", tc.err)
+			}
+			if isRecoverable(got) != tc.recoverable {
+				t.Fatalf("classifyError(%q) recoverable = %v, want %v", tc.err, isRecoverable(got), tc.recoverable)
+			}
+		})
+	}
+}
+
+func TestClassifyErrorNil(t *testing.T) {
+	if got := classifyError(nil); got != nil {
+		t.Fatalf("classifyError(nil) = %v, want nil", got)
+	}
+}
+
+func TestClassifyErrorPreservesExistingClassification(t *testing.T) {
+	original := newRecoverableError(errors.New("boom"))
+	if got := classifyError(original); got != error(original) {
+		t.Fatalf("classifyError should pass through an error that already implements recoverabler unchanged")
+	}
+}
+
+func TestIsRecoverable(t *testing.T) {
+	if isRecoverable(nil) {
+		t.Fatal("nil should not be recoverable")
+	}
+	if isRecoverable(errors.New("plain error")) {
+		t.Fatal("a plain error not run through classifyError should not be considered recoverable")
+	}
+	if !isRecoverable(newRecoverableError(errors.New("boom"))) {
+		t.Fatal("a recoverableError should report itself as recoverable")
+	}
+}
+
+func TestWithRetrySucceedsWithoutRetrying(t *testing.T) {
+	calls := 0
+	err := withRetry(3, time.Millisecond, func() error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestWithRetryRetriesRecoverableErrors(t *testing.T) {
+	calls := 0
+	err := withRetry(3, time.Millisecond, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("temporary timeout")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestWithRetryStopsOnUnrecoverableError(t *testing.T) {
+	calls := 0
+	err := withRetry(5, time.Millisecond, func() error {
+		calls++
+		return errors.New("Vault is already initialized")
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 1 {
+		t.Fatalf("expected exactly 1 call for an unrecoverable error, got %d", calls)
+	}
+}
+
+func TestWithRetryGivesUpAfterAttemptsExhausted(t *testing.T) {
+	calls := 0
+	err := withRetry(3, time.Millisecond, func() error {
+		calls++
+		return errors.New("connection timeout")
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestWithRetryTreatsLessThanOneAttemptAsOne(t *testing.T) {
+	calls := 0
+	_ = withRetry(0, time.Millisecond, func() error {
+		calls++
+		return errors.New("connection timeout")
+	})
+	if calls != 1 {
+		t.Fatalf("expected 1 call when attempts <= 0, got %d", calls)
+	}
+}